@@ -0,0 +1,101 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * HTTP endpoints for job status and per-job log retrieval
+ *
+ * @file api.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 22:25:00 2026
+ *
+ **/
+
+package api
+
+import (
+	"fmt"
+	"github.com/crackcell/hpipe/log"
+	"github.com/crackcell/hpipe/status"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// Serve exposes the scheduler's job status and per-job log files over
+// HTTP:
+//
+//	GET /jobs                      - list every job's current status
+//	GET /jobs/{name}/log?attempt=N - stream the log file for one attempt
+//	                                  (latest attempt if omitted)
+//
+// It blocks for the lifetime of the scheduler process; run it in its
+// own goroutine.
+func Serve(addr string, tracker *status.StatusTracker, logger *log.JobLogger) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		handleListJobs(w, r, tracker)
+	})
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		handleJobLog(w, r, logger)
+	})
+	return http.ListenAndServe(addr, mux)
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+func handleListJobs(w http.ResponseWriter, r *http.Request, tracker *status.StatusTracker) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, tracker.ToJson())
+}
+
+func handleJobLog(w http.ResponseWriter, r *http.Request, logger *log.JobLogger) {
+	name, ok := parseJobName(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	attempt := 0
+	if a := r.URL.Query().Get("attempt"); a != "" {
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			http.Error(w, "invalid attempt", http.StatusBadRequest)
+			return
+		}
+		attempt = n
+	}
+
+	record, ok := logger.Record(name, attempt)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeFile(w, r, record.Path)
+}
+
+// parseJobName extracts name from a "/jobs/{name}/log" path.
+func parseJobName(path string) (string, bool) {
+	const prefix = "/jobs/"
+	const suffix = "/log"
+	if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, suffix) {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix)
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
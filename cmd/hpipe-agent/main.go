@@ -0,0 +1,188 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * hpipe-agent connects out to a hpipe scheduler running in --server
+ * mode and runs the jobs it is dispatched, over the same websocket
+ * JSON-RPC 2.0 transport exec.RemoteExec speaks on the scheduler side.
+ *
+ * @file main.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:45:00 2026
+ *
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/crackcell/hpipe/log"
+	"github.com/crackcell/hpipe/task/exec"
+	"github.com/gorilla/websocket"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+func main() {
+	flag.Parse()
+
+	u := url.URL{Scheme: "ws", Host: connectAddr, Path: "/agent"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		log.Fatalf("cannot connect to scheduler %s: %v", connectAddr, err)
+		return
+	}
+	defer conn.Close()
+
+	if err := register(conn); err != nil {
+		log.Fatalf("cannot register with scheduler: %v", err)
+		return
+	}
+
+	sem := make(chan struct{}, concurrency)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			log.Fatalf("disconnected from scheduler: %v", err)
+			return
+		}
+
+		req := exec.RPCRequest{}
+		if err := json.Unmarshal(data, &req); err != nil {
+			log.Errorf("invalid rpc frame: %v", err)
+			continue
+		}
+
+		switch req.Method {
+		case "job.run":
+			sem <- struct{}{}
+			go func(req exec.RPCRequest) {
+				defer func() { <-sem }()
+				runJob(conn, req)
+			}(req)
+		case "job.action":
+			sem <- struct{}{}
+			go func(req exec.RPCRequest) {
+				defer func() { <-sem }()
+				runAction(conn, req)
+			}(req)
+		}
+	}
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+var (
+	connectAddr string
+	jobTypes    string
+	concurrency int
+)
+
+// writeMu serializes every WriteJSON call on conn: -concurrency lets
+// job.run/job.action handlers run concurrently, and gorilla/websocket
+// allows only one writer on a connection at a time.
+var writeMu sync.Mutex
+
+func writeJSON(conn *websocket.Conn, v interface{}) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func init() {
+	flag.StringVar(&connectAddr, "connect", "localhost:9090", "scheduler address to connect out to")
+	flag.StringVar(&jobTypes, "types", "script", "comma separated list of job types this agent can run")
+	flag.IntVar(&concurrency, "concurrency", 1, "max number of jobs this agent runs at once")
+}
+
+func register(conn *websocket.Conn) error {
+	params, err := json.Marshal(exec.AgentRegisterParams{
+		JobTypes:       strings.Split(jobTypes, ","),
+		MaxConcurrency: concurrency,
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(conn, exec.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "agent.register",
+		Params:  params,
+	})
+}
+
+func runJob(conn *websocket.Conn, req exec.RPCRequest) {
+	params := exec.JobRunParams{}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		respond(conn, req.ID, "Failed")
+		return
+	}
+
+	log.Infof("run job: %s", params.Name)
+
+	cmd := params.Var["cmd"]
+	args := strings.Fields(params.Var["args"])
+
+	_, err := exec.CmdExecWithSink(params.Name, func(stream, line string) {
+		streamLog(conn, params.Name, stream, line)
+	}, cmd, args...)
+
+	status := "Finished"
+	if err != nil {
+		log.Errorf("job %s failed: %v", params.Name, err)
+		status = "Failed"
+	}
+	respond(conn, req.ID, status)
+}
+
+func runAction(conn *websocket.Conn, req exec.RPCRequest) {
+	params := exec.JobActionParams{}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		respond(conn, req.ID, "Failed")
+		return
+	}
+
+	log.Infof("run action %s on job %s", params.Action, params.Name)
+
+	fields := strings.Fields(params.Cmd)
+	if len(fields) == 0 {
+		log.Errorf("action %s on job %s has an empty cmd", params.Action, params.Name)
+		respond(conn, req.ID, "Failed")
+		return
+	}
+	args := append(fields[1:], params.Args...)
+
+	_, err := exec.CmdExecWithSink(params.Name, func(stream, line string) {
+		streamLog(conn, params.Name, stream, line)
+	}, fields[0], args...)
+
+	status := "Finished"
+	if err != nil {
+		log.Errorf("action %s on job %s failed: %v", params.Action, params.Name, err)
+		status = "Failed"
+	}
+	respond(conn, req.ID, status)
+}
+
+func respond(conn *websocket.Conn, id uint64, status string) {
+	result, _ := json.Marshal(exec.JobStatusParams{Status: status})
+	writeJSON(conn, exec.RPCResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func streamLog(conn *websocket.Conn, name, stream, line string) {
+	params, _ := json.Marshal(exec.JobStreamLogParams{Name: name, Stream: stream, Line: line})
+	writeJSON(conn, exec.RPCRequest{JSONRPC: "2.0", Method: "job.stream_log", Params: params})
+}
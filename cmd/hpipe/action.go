@@ -0,0 +1,98 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * `hpipe action <jobname> <action> [-- args...]`
+ *
+ * Resolves jobname in the flow loaded from config.EntryFile, runs one
+ * of the Actions attached to its definition outside the normal DAG run,
+ * and streams its stdout/stderr back over the same websocket/JSON-RPC
+ * transport exec.RemoteExec uses for regular runs. Dispatched from
+ * main() in main.go.
+ *
+ * For a job parsed from a file no action is run: dag.Build does not
+ * convert a parsed flow.Job's actions onto the resulting *dag.Job (see
+ * flow.ActionJob, dag.Job.SetActions), so resolveJob returns a job with
+ * zero Actions. runAction checks that and exits up front with an
+ * explicit "no actions wired up" error, before setting up a scheduler,
+ * so the failure is not mistaken for an unknown action name.
+ *
+ * @file action.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 23:00:00 2026
+ *
+ **/
+
+package main
+
+import (
+	"fmt"
+	"github.com/crackcell/hpipe/config"
+	"github.com/crackcell/hpipe/dag"
+	"github.com/crackcell/hpipe/log"
+	"github.com/crackcell/hpipe/sched"
+	"github.com/crackcell/hpipe/status"
+	"os"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// runAction handles `hpipe ... action <jobname> <action> [-- args...]`,
+// args being flag.Args() with "action" still in args[0].
+func runAction(args []string) {
+	if len(args) < 3 {
+		fmt.Fprintln(os.Stderr, "usage: hpipe -flow <entry> -work <workdir> action <jobname> <action> [-- args...]")
+		os.Exit(1)
+	}
+	jobname, actionName, extra := args[1], args[2], args[3:]
+	if len(extra) > 0 && extra[0] == "--" {
+		extra = extra[1:]
+	}
+
+	job, err := resolveJob(jobname)
+	if err != nil {
+		log.Fatalf("cannot resolve job %s: %v", jobname, err)
+	}
+	if len(job.Actions) == 0 {
+		log.Fatalf("job %s has no actions wired up: dag.Build does not yet carry a "+
+			"parsed job's actions onto its *dag.Job, so no file-defined job has any "+
+			"until that's fixed (see flow.ActionJob)", jobname)
+	}
+
+	tracker := status.NewStatusTracker(config.MetaPath)
+	s, err := sched.NewSched(tracker)
+	if err != nil {
+		log.Fatalf("cannot init executors: %v", err)
+	}
+
+	if err := s.RunAction(job, actionName, extra, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("action %s on job %s failed: %v", actionName, jobname, err)
+	}
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+// resolveJob builds the DAG main.go's regular run would, via buildDAG,
+// and looks jobname up in it.
+func resolveJob(jobname string) (*dag.Job, error) {
+	d, err := buildDAG()
+	if err != nil {
+		return nil, err
+	}
+
+	job, ok := d.Jobs[jobname]
+	if !ok {
+		return nil, fmt.Errorf("no such job: %s", jobname)
+	}
+	return job, nil
+}
@@ -0,0 +1,77 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * hpipe entrypoint: a regular scheduled run, or `action <job> <action>`
+ *
+ * `hpipe -flow <entry> -work <workdir>` parses config.EntryFile with
+ * whichever flow.Parser its extension dispatches to and runs the
+ * resulting DAG to completion. `hpipe ... action <jobname> <action>
+ * [-- args...]` is handled by runAction in action.go instead.
+ *
+ * @file main.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 23:58:00 2026
+ *
+ **/
+
+package main
+
+import (
+	"flag"
+	"github.com/crackcell/hpipe/config"
+	"github.com/crackcell/hpipe/dag"
+	"github.com/crackcell/hpipe/flow"
+	"github.com/crackcell/hpipe/log"
+	"github.com/crackcell/hpipe/sched"
+	"github.com/crackcell/hpipe/status"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+func main() {
+	config.InitFlags()
+	config.Parse()
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "action" {
+		runAction(args)
+		return
+	}
+
+	d, err := buildDAG()
+	if err != nil {
+		log.Fatalf("cannot build dag: %v", err)
+	}
+
+	tracker := status.NewStatusTracker(config.MetaPath)
+	s, err := sched.NewSched(tracker)
+	if err != nil {
+		log.Fatalf("cannot init executors: %v", err)
+	}
+
+	if err := s.Run(d); err != nil {
+		log.Fatalf("run failed: %v", err)
+	}
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+// buildDAG parses config.EntryFile with the flow.Parser its extension
+// dispatches to, the same entry point sched/reload.go re-parses from on
+// every hot reload and action.go's resolveJob resolves a single job
+// from.
+func buildDAG() (*dag.DAG, error) {
+	parser := flow.NewParserForFile(config.EntryFile)
+	step := parser.ParseStepFromFile(config.EntryFile, config.WorkPath)
+	return dag.Build(step)
+}
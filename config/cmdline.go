@@ -28,12 +28,16 @@ import (
 //===================================================================
 
 var (
-	Help      bool
-	Verbose   bool
-	WorkPath  string
-	MetaPath  string
-	NodeName  string
-	EntryFile string
+	Help       bool
+	Verbose    bool
+	WorkPath   string
+	MetaPath   string
+	NodeName   string
+	EntryFile  string
+	Server     bool
+	AgentAddr  string
+	ConsulAddr string
+	ApiAddr    string
 )
 
 func InitFlags() {
@@ -49,6 +53,10 @@ func InitFlags() {
 	flag.StringVar(&MetaPath, "m", "", "Path for meta data")
 	flag.StringVar(&EntryFile, "flow", "", "Entry of the flow")
 	flag.StringVar(&EntryFile, "f", "", "Entry of the flow")
+	flag.BoolVar(&Server, "server", false, "Run as a scheduler that dispatches jobs to remote hpipe-agent workers")
+	flag.StringVar(&AgentAddr, "agent-addr", ":9090", "Listen address for hpipe-agent websocket connections")
+	flag.StringVar(&ConsulAddr, "consul", "", "Consul HTTP API address for dynamic executor discovery, e.g. 127.0.0.1:8500")
+	flag.StringVar(&ApiAddr, "api-addr", ":8080", "Listen address for the job status/log HTTP API")
 }
 
 func Parse() {
@@ -57,4 +65,4 @@ func Parse() {
 
 //===================================================================
 // Private
-//===================================================================
\ No newline at end of file
+//===================================================================
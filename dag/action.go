@@ -0,0 +1,54 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * on-demand actions attached to a job, outside the dependency graph
+ *
+ * @file action.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 22:45:00 2026
+ *
+ **/
+
+package dag
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// Action is a named, on-demand operation attached to a Job that sits
+// outside the DAG's dependency graph: an operator can invoke it
+// directly (see exec.Exec.RunAction) without the job's deps being
+// satisfied. Typical examples are a checkpoint rerun, a counter dump or
+// a kill command for a stuck YARN application.
+type Action struct {
+	Name string
+	Cmd  string
+}
+
+// SetActions stores actions as this job's Actions. dag.Build does not
+// call it when it converts a parsed flow.Job into the *Job the scheduler
+// runs, so every *Job built from a parsed file has no Actions.
+func (this *Job) SetActions(actions []Action) {
+	this.Actions = actions
+}
+
+// FindAction returns the named Action attached to this job, if any.
+func (this *Job) FindAction(name string) (*Action, bool) {
+	for i := range this.Actions {
+		if this.Actions[i].Name == name {
+			return &this.Actions[i], true
+		}
+	}
+	return nil, false
+}
+
+//===================================================================
+// Private
+//===================================================================
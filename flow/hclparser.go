@@ -0,0 +1,151 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * HCL parser, modeled on the Nomad jobspec
+ *
+ * @file hclparser.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:30:00 2026
+ *
+ **/
+
+package flow
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+func NewHCLParser() Parser {
+	return new(hclParser)
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+// Shapes of the form:
+//
+//	step "name" {
+//	  var {
+//	    k = "v"
+//	  }
+//	  dep "res" {
+//	    var { k = "v" }
+//	  }
+//	  do "res" {
+//	    arg { k = "v" }
+//	  }
+//	}
+
+type hclStepFile struct {
+	Step map[string]*hclStep `hcl:"step"`
+}
+
+type hclStep struct {
+	Var map[string]interface{} `hcl:"var"`
+	Dep map[string]*hclDep     `hcl:"dep"`
+	Do  map[string]*hclDo      `hcl:"do"`
+}
+
+type hclDep struct {
+	Var map[string]interface{} `hcl:"var"`
+}
+
+type hclDo struct {
+	Arg map[string]interface{} `hcl:"arg"`
+}
+
+type hclJobFile struct {
+	Job map[string]*hclJobBlock `hcl:"job"`
+}
+
+type hclJobBlock struct {
+	Type   string                 `hcl:"type"`
+	Var    map[string]interface{} `hcl:"var"`
+	Action map[string]*hclAction  `hcl:"action"`
+}
+
+// hclAction is:
+//
+//	action "kill-yarn-app" {
+//	  cmd = "yarn application -kill ${var.app_id}"
+//	}
+type hclAction struct {
+	Cmd string `hcl:"cmd"`
+}
+
+type hclParser struct{}
+
+func (this *hclParser) ParseStepFromFile(entry string, workdir string) *Step {
+	return loadStepFromFile(entry, workdir, nil, decodeHCLStep, decodeHCLJob)
+}
+
+func (this *hclParser) ParseJobFromFile(entry string, workdir string) Job {
+	return loadJobFromFile(entry, workdir, nil, decodeHCLJob)
+}
+
+func decodeHCLStep(data []byte) (*stepIR, error) {
+	f := hclStepFile{}
+	if err := hcl.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Step) != 1 {
+		return nil, fmt.Errorf("expect exactly one step block, got %d", len(f.Step))
+	}
+
+	var name string
+	var s *hclStep
+	for k, v := range f.Step {
+		name, s = k, v
+	}
+
+	ir := &stepIR{
+		Name: name,
+		Var:  flattenVars(s.Var),
+	}
+	for res, do := range s.Do {
+		ir.Do = append(ir.Do, doIR{Res: res, Arg: flattenVars(do.Arg)})
+	}
+	for res, dep := range s.Dep {
+		ir.Dep = append(ir.Dep, depIR{Res: res, Var: flattenVars(dep.Var)})
+	}
+	return ir, nil
+}
+
+func decodeHCLJob(data []byte) (*jobIR, error) {
+	f := hclJobFile{}
+	if err := hcl.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Job) != 1 {
+		return nil, fmt.Errorf("expect exactly one job block, got %d", len(f.Job))
+	}
+
+	var name string
+	var j *hclJobBlock
+	for k, v := range f.Job {
+		name, j = k, v
+	}
+
+	ir := &jobIR{
+		Name: name,
+		Type: j.Type,
+		Var:  flattenVars(j.Var),
+	}
+	for actionName, action := range j.Action {
+		ir.Action = append(ir.Action, actionIR{Name: actionName, Cmd: action.Cmd})
+	}
+	return ir, nil
+}
@@ -0,0 +1,258 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * format-agnostic intermediate representation shared by every parser
+ *
+ * @file ir.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:30:00 2026
+ *
+ **/
+
+package flow
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+//===================================================================
+// Private
+//===================================================================
+
+// stepIR is the canonical, format-agnostic representation of a <step>
+// block. Every concrete parser (xmlParser, hclParser, yamlParser) only
+// needs to produce one of these from its own file format; the walking
+// and variable resolution logic in loadStepFromFile/loadJobFromFile is
+// shared.
+type stepIR struct {
+	Name string
+	Var  []string
+	Dep  []depIR
+	Do   []doIR
+}
+
+type depIR struct {
+	Res string
+	Var []string
+}
+
+type doIR struct {
+	Res string
+	Arg []string
+}
+
+// jobIR is the canonical representation of a <job> block.
+type jobIR struct {
+	Name   string
+	Type   string
+	Var    []string
+	Action []actionIR
+}
+
+// actionIR is a named, on-demand operation attached to a job that sits
+// outside the DAG's dependency graph (see Action).
+type actionIR struct {
+	Name string
+	Cmd  string
+}
+
+// Action is the parsed name/cmd pair for one <action>/action{} block
+// attached to a job definition. It's parser output only - Job.SetActions
+// just stores the slice verbatim, and dag.Build does not turn it into a
+// dag.Action on the *dag.Job the scheduler actually runs.
+type Action struct {
+	Name string
+	Cmd  string
+}
+
+// ActionJob is implemented by any Job whose actions a parser populated.
+// It's exported so a caller in a different package can type-assert a
+// parsed Job against it and read its actions - the same
+// optional-interface pattern sched.Sched.RunAction uses against
+// exec.Exec. dag.Build does not make that type assertion, so
+// `hpipe action <job> <action>` exits with a "no actions wired up"
+// error for every job parsed from a file (see cmd/hpipe/action.go).
+type ActionJob interface {
+	Actions() []Action
+}
+
+// stepDecoder turns the raw bytes of a step file into a stepIR.
+type stepDecoder func(data []byte) (*stepIR, error)
+
+// jobDecoder turns the raw bytes of a job file into a jobIR.
+type jobDecoder func(data []byte) (*jobIR, error)
+
+func loadStepFromFile(entry string, workdir string, preDefinedVars map[string]string,
+	decodeStep stepDecoder, decodeJob jobDecoder) *Step {
+
+	entry = workdir + "/" + entry
+
+	data, err := ioutil.ReadFile(entry)
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	s, err := decodeStep(data)
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	step := NewStep()
+	step.Name = s.Name
+
+	step.Var = arrayToMap(s.Var, "=")
+
+	step.Var, err = evalMap(preDefinedVars, step.Var)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, do := range s.Do {
+		localVar := arrayToMap(do.Arg, "=")
+		localVar, err := evalMap(preDefinedVars, step.Var, localVar)
+		if err != nil {
+			panic(err)
+		}
+		step.Do = append(step.Do,
+			loadJobFromFile(do.Res, workdir, localVar, decodeJob))
+	}
+
+	for _, dep := range s.Dep {
+		localVar := arrayToMap(dep.Var, "=")
+		localVar, err := evalMap(preDefinedVars, step.Var, localVar)
+		if err != nil {
+			panic(err)
+		}
+		step.Dep = append(step.Dep,
+			loadStepFromFile(dep.Res, workdir, localVar, decodeStep, decodeJob))
+	}
+
+	return step
+}
+
+func loadJobFromFile(entry string, workdir string, preDefinedVars map[string]string,
+	decodeJob jobDecoder) Job {
+
+	entry = workdir + "/" + entry
+
+	data, err := ioutil.ReadFile(entry)
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	j, err := decodeJob(data)
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	var job Job
+
+	switch j.Type {
+	case "odps":
+		job = NewODPSJob()
+	case "hadoop":
+		job = NewHadoopJob()
+	default:
+		log.Panic("unknown job type")
+	}
+	job.SetName(j.Name)
+
+	localVar := arrayToMap(j.Var, "=")
+	localVar, err = evalMap(preDefinedVars, localVar)
+	if err != nil {
+		panic(err)
+	}
+	job.SetVar(localVar)
+	if !job.IsValid() {
+		panic("job is invalid")
+	}
+
+	if len(j.Action) != 0 {
+		actions := make([]Action, 0, len(j.Action))
+		for _, a := range j.Action {
+			actions = append(actions, Action{Name: a.Name, Cmd: a.Cmd})
+		}
+		job.SetActions(actions)
+	}
+
+	return job
+}
+
+// flattenVars turns the native types a format like HCL or YAML can
+// express for a var block (numbers, lists, nested maps) into the "k=v"
+// string pairs arrayToMap/evalMap already know how to evaluate. A
+// nested map's keys are joined with "." and a list's with its index, so
+// e.g. {nested: {a: 1, b: 2}} becomes "nested.a=1", "nested.b=2" rather
+// than collapsing into a single Go-syntax blob - each leaf stays a
+// separately addressable, individually interpolated variable.
+// Interpolation syntax such as ${var.foo} is left untouched so
+// calc.Calc resolves it exactly as it does for XML's ${...} variables.
+func flattenVars(m map[string]interface{}) []string {
+	vars := make([]string, 0, len(m))
+	for k, v := range m {
+		vars = append(vars, flattenVar(k, v)...)
+	}
+	return vars
+}
+
+// flattenVar recurses into maps and slices, naming each leaf prefix.k
+// or prefix.N, and renders everything else with "%v". Nested maps don't
+// come back from every decoder as a plain map[string]interface{}:
+// gopkg.in/yaml.v2 decodes a nested mapping as
+// map[interface{}]interface{}, and hashicorp/hcl decodes a nested
+// block or map as []map[string]interface{} (always one element for a
+// single block). Both are normalized here so a var block nests the
+// same way regardless of which parser produced it.
+func flattenVar(prefix string, v interface{}) []string {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return flattenMapVar(prefix, val)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, nested := range val {
+			m[fmt.Sprintf("%v", k)] = nested
+		}
+		return flattenMapVar(prefix, m)
+	case []map[string]interface{}:
+		vars := make([]string, 0, len(val))
+		for _, elem := range val {
+			vars = append(vars, flattenMapVar(prefix, elem)...)
+		}
+		return vars
+	case []interface{}:
+		vars := make([]string, 0, len(val))
+		for i, elem := range val {
+			vars = append(vars, flattenVar(fmt.Sprintf("%s.%d", prefix, i), elem)...)
+		}
+		return vars
+	default:
+		return []string{fmt.Sprintf("%s=%v", prefix, val)}
+	}
+}
+
+// flattenMapVar flattens one already-normalized map[string]interface{}
+// level, joining each key onto prefix with ".".
+func flattenMapVar(prefix string, m map[string]interface{}) []string {
+	vars := make([]string, 0, len(m))
+	for k, nested := range m {
+		vars = append(vars, flattenVar(prefix+"."+k, nested)...)
+	}
+	return vars
+}
@@ -0,0 +1,53 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * extension-driven Parser dispatch
+ *
+ * @file registry.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:30:00 2026
+ *
+ **/
+
+package flow
+
+import (
+	"log"
+	"path/filepath"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// NewParserForFile picks the Parser registered for entry's file
+// extension, so config.EntryFile can be handed straight to it without
+// the caller having to know whether the flow is written in XML, HCL or
+// YAML. Every call site that loads config.EntryFile goes through this
+// instead of hardcoding flow.NewXMLParser(): the regular scheduled run
+// in cmd/hpipe/main.go, sched/reload.go's hot reload, and
+// cmd/hpipe/action.go's job resolution.
+func NewParserForFile(entry string) Parser {
+	switch filepath.Ext(entry) {
+	case ".xml":
+		return NewXMLParser()
+	case ".hcl":
+		return NewHCLParser()
+	case ".yaml", ".yml":
+		return NewYAMLParser()
+	default:
+		log.Fatalf("unsupported flow format: %s", entry)
+		return nil
+	}
+}
+
+//===================================================================
+// Private
+//===================================================================
@@ -22,7 +22,6 @@ import (
 	"encoding/xml"
 	_ "fmt"
 	"github.com/crackcell/opipe/calc"
-	"io/ioutil"
 	"log"
 	"strings"
 )
@@ -60,10 +59,21 @@ type XMLDo struct {
 }
 
 type XMLJob struct {
-	XMLName xml.Name `xml:"job"`
+	XMLName xml.Name    `xml:"job"`
+	Name    string      `xml:"name,attr"`
+	Type    string      `xml:"type,attr"`
+	Var     []string    `xml:"var"`
+	Action  []XMLAction `xml:"action"`
+}
+
+// XMLAction is a named, on-demand operation attached to a job that sits
+// outside the DAG's dependency graph, e.g.:
+//
+//	<action name="kill-yarn-app"><cmd>yarn application -kill ${app.id}</cmd></action>
+type XMLAction struct {
+	XMLName xml.Name `xml:"action"`
 	Name    string   `xml:"name,attr"`
-	Type    string   `xml:"type,attr"`
-	Var     []string `xml:"var"`
+	Cmd     string   `xml:"cmd"`
 }
 
 type xmlParser struct{}
@@ -78,112 +88,44 @@ func (this *xmlParser) ParseJobFromFile(entry string, workdir string) Job {
 
 func parseStepFromFile(entry string, workdir string,
 	preDefinedVars map[string]string) *Step {
+	return loadStepFromFile(entry, workdir, preDefinedVars, decodeXMLStep, decodeXMLJob)
+}
 
-	entry = workdir + "/" + entry
-
-	//log.Println("open:", entry)
-	data, err := ioutil.ReadFile(entry)
-	if err != nil {
-		log.Fatal(err)
-		return nil
-	}
+func parseJobFromFile(entry string, workdir string,
+	preDefinedVars map[string]string) Job {
+	return loadJobFromFile(entry, workdir, preDefinedVars, decodeXMLJob)
+}
 
+func decodeXMLStep(data []byte) (*stepIR, error) {
 	s := XMLStep{}
 	if err := xml.Unmarshal(data, &s); err != nil {
-		return nil
+		return nil, err
 	}
 
-	//log.Println(s)
-
-	step := NewStep()
-	step.Name = s.Name
-
-	step.Var = arrayToMap(s.Var, "=")
-
-	step.Var, err = evalMap(preDefinedVars, step.Var)
-	if err != nil {
-		panic(err)
+	ir := &stepIR{
+		Name: s.Name,
+		Var:  s.Var,
 	}
-
 	for _, do := range s.Do {
-		localVar := arrayToMap(do.Arg, "=")
-		//log.Printf("%s ============\n", entry)
-		//log.Printf("predef: %v\n", preDefinedVars)
-		//log.Printf("step.Var: %v\n", step.Var)
-		//log.Printf("localVar: %v\n", localVar)
-		localVar, err := evalMap(preDefinedVars, step.Var, localVar)
-		if err != nil {
-			panic(err)
-		}
-		//log.Printf("output: %v\n", localVar)
-		step.Do = append(step.Do,
-			parseJobFromFile(do.Res, workdir, localVar))
+		ir.Do = append(ir.Do, doIR{Res: do.Res, Arg: do.Arg})
 	}
-
 	for _, dep := range s.Dep {
-		localVar := arrayToMap(dep.Var, "=")
-		//log.Printf("%s ============\n", entry)
-		//log.Printf("predef: %v\n", preDefinedVars)
-		//log.Printf("step.Var: %v\n", step.Var)
-		//log.Printf("localVar: %v\n", localVar)
-		localVar, err := evalMap(preDefinedVars, step.Var, localVar)
-		if err != nil {
-			panic(err)
-		}
-		//log.Printf("output: %v\n", localVar)
-		step.Dep = append(step.Dep,
-			parseStepFromFile(dep.Res, workdir, localVar))
+		ir.Dep = append(ir.Dep, depIR{Res: dep.Res, Var: dep.Var})
 	}
-
-	return step
+	return ir, nil
 }
 
-func parseJobFromFile(entry string, workdir string,
-	preDefinedVars map[string]string) Job {
-
-	entry = workdir + "/" + entry
-
-	//log.Println("open:", entry)
-	data, err := ioutil.ReadFile(entry)
-	if err != nil {
-		log.Fatal(err)
-		return nil
-	}
-
+func decodeXMLJob(data []byte) (*jobIR, error) {
 	j := XMLJob{}
 	if err := xml.Unmarshal(data, &j); err != nil {
-		return nil
+		return nil, err
 	}
 
-	//log.Println(j)
-
-	var job Job
-
-	switch j.Type {
-	case "odps":
-		job = NewODPSJob()
-	case "hadoop":
-		job = NewHadoopJob()
-	default:
-		log.Panic("unknown job type")
+	ir := &jobIR{Name: j.Name, Type: j.Type, Var: j.Var}
+	for _, a := range j.Action {
+		ir.Action = append(ir.Action, actionIR{Name: a.Name, Cmd: a.Cmd})
 	}
-	job.SetName(j.Name)
-
-	localVar := arrayToMap(j.Var, "=")
-	//log.Printf("%s ============\n", entry)
-	//log.Printf("predef: %v\n", preDefinedVars)
-	//log.Printf("evalMap: %v\n", localVar)
-	localVar, err = evalMap(preDefinedVars, localVar)
-	if err != nil {
-		panic(err)
-	}
-	//log.Printf("output: %v\n", localVar)
-	job.SetVar(localVar)
-	if !job.IsValid() {
-		panic("job is invalid")
-	}
-
-	return job
+	return ir, nil
 }
 
 func updateMap(dest map[string]string, src map[string]string) {
@@ -221,4 +163,4 @@ func evalMap(maps ...map[string]string) (map[string]string, error) {
 		return nil, err
 	}
 	return output, nil
-}
\ No newline at end of file
+}
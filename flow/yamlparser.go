@@ -0,0 +1,106 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * YAML parser
+ *
+ * @file yamlparser.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:30:00 2026
+ *
+ **/
+
+package flow
+
+import (
+	"gopkg.in/yaml.v2"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+func NewYAMLParser() Parser {
+	return new(yamlParser)
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+type yamlStep struct {
+	Name string                 `yaml:"name"`
+	Var  map[string]interface{} `yaml:"var"`
+	Dep  []yamlDep              `yaml:"dep"`
+	Do   []yamlDo               `yaml:"do"`
+}
+
+type yamlDep struct {
+	Res string                 `yaml:"res"`
+	Var map[string]interface{} `yaml:"var"`
+}
+
+type yamlDo struct {
+	Res string                 `yaml:"res"`
+	Arg map[string]interface{} `yaml:"arg"`
+}
+
+type yamlJob struct {
+	Name   string                 `yaml:"name"`
+	Type   string                 `yaml:"type"`
+	Var    map[string]interface{} `yaml:"var"`
+	Action []yamlAction           `yaml:"action"`
+}
+
+type yamlAction struct {
+	Name string `yaml:"name"`
+	Cmd  string `yaml:"cmd"`
+}
+
+type yamlParser struct{}
+
+func (this *yamlParser) ParseStepFromFile(entry string, workdir string) *Step {
+	return loadStepFromFile(entry, workdir, nil, decodeYAMLStep, decodeYAMLJob)
+}
+
+func (this *yamlParser) ParseJobFromFile(entry string, workdir string) Job {
+	return loadJobFromFile(entry, workdir, nil, decodeYAMLJob)
+}
+
+func decodeYAMLStep(data []byte) (*stepIR, error) {
+	s := yamlStep{}
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	ir := &stepIR{
+		Name: s.Name,
+		Var:  flattenVars(s.Var),
+	}
+	for _, do := range s.Do {
+		ir.Do = append(ir.Do, doIR{Res: do.Res, Arg: flattenVars(do.Arg)})
+	}
+	for _, dep := range s.Dep {
+		ir.Dep = append(ir.Dep, depIR{Res: dep.Res, Var: flattenVars(dep.Var)})
+	}
+	return ir, nil
+}
+
+func decodeYAMLJob(data []byte) (*jobIR, error) {
+	j := yamlJob{}
+	if err := yaml.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+
+	ir := &jobIR{Name: j.Name, Type: j.Type, Var: flattenVars(j.Var)}
+	for _, a := range j.Action {
+		ir.Action = append(ir.Action, actionIR{Name: a.Name, Cmd: a.Cmd})
+	}
+	return ir, nil
+}
@@ -0,0 +1,195 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * per-job log capture and persistence, Harbor job_log style
+ *
+ * @file joblogger.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 22:25:00 2026
+ *
+ **/
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// JobRecord is the bookkeeping JobLogger keeps for one attempt of one
+// job: where its output landed and how long it took to run.
+type JobRecord struct {
+	Name      string
+	Attempt   int
+	Path      string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// JobLogger writes one file per job attempt under
+// <metaPath>/logs/<bizdate>/<jobname>.<attempt>.log.
+type JobLogger struct {
+	metaPath string
+
+	mu      sync.Mutex
+	open    map[string]*os.File
+	records map[string][]*JobRecord
+}
+
+func NewJobLogger(metaPath string) *JobLogger {
+	return &JobLogger{
+		metaPath: metaPath,
+		open:     make(map[string]*os.File),
+		records:  make(map[string][]*JobRecord),
+	}
+}
+
+// Open reserves the next attempt log file for jobname on bizdate. Call
+// Close(jobname) once the job finishes running.
+func (this *JobLogger) Open(bizdate, jobname string) (*JobRecord, error) {
+	dir := filepath.Join(this.metaPath, "logs", bizdate)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	attempt := nextAttempt(dir, jobname)
+	path := filepath.Join(dir, fmt.Sprintf("%s.%d.log", jobname, attempt))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &JobRecord{
+		Name:      jobname,
+		Attempt:   attempt,
+		Path:      path,
+		StartTime: time.Now(),
+	}
+
+	this.mu.Lock()
+	this.open[jobname] = f
+	this.records[jobname] = append(this.records[jobname], record)
+	this.mu.Unlock()
+
+	return record, nil
+}
+
+// Sink returns a CmdExecWithSink-compatible callback that timestamps
+// every stdout/stderr line and appends it to jobname's currently open
+// attempt file.
+func (this *JobLogger) Sink(jobname string) func(stream, line string) {
+	return func(stream, line string) {
+		this.mu.Lock()
+		f := this.open[jobname]
+		this.mu.Unlock()
+		if f == nil {
+			return
+		}
+		fmt.Fprintf(f, "%s [%s] %s\n", time.Now().Format(time.RFC3339), stream, line)
+	}
+}
+
+// Close stops capture for jobname's currently open attempt and stamps
+// its end time.
+func (this *JobLogger) Close(jobname string) error {
+	this.mu.Lock()
+	f := this.open[jobname]
+	delete(this.open, jobname)
+	if records := this.records[jobname]; len(records) > 0 {
+		records[len(records)-1].EndTime = time.Now()
+	}
+	this.mu.Unlock()
+
+	if f == nil {
+		return nil
+	}
+	return f.Close()
+}
+
+// Record returns jobname's record for the given attempt, or its most
+// recent attempt if attempt is 0.
+func (this *JobLogger) Record(jobname string, attempt int) (*JobRecord, bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	records := this.records[jobname]
+	if len(records) == 0 {
+		return nil, false
+	}
+	if attempt == 0 {
+		return records[len(records)-1], true
+	}
+	for _, r := range records {
+		if r.Attempt == attempt {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Jobs returns the name of every job JobLogger has ever opened a log
+// for, sorted for stable listing.
+func (this *JobLogger) Jobs() []string {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	names := make([]string, 0, len(this.records))
+	for name := range this.records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+var attemptFileRe = regexp.MustCompile(`\.(\d+)\.log$`)
+
+// nextAttempt scans dir for existing <jobname>.N.log files and returns
+// N+1 for the highest N found.
+func nextAttempt(dir, jobname string) int {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 1
+	}
+
+	max := 0
+	prefix := jobname + "."
+	for _, e := range entries {
+		name := e.Name()
+		if len(name) <= len(prefix) || name[:len(prefix)] != prefix {
+			continue
+		}
+		m := attemptFileRe.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		if name != prefix+m[1]+".log" {
+			continue
+		}
+		if n, err := strconv.Atoi(m[1]); err == nil && n > max {
+			max = n
+		}
+	}
+	return max + 1
+}
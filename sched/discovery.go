@@ -0,0 +1,186 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * Consul-backed dynamic executor discovery
+ *
+ * @file discovery.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 22:10:00 2026
+ *
+ **/
+
+package sched
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crackcell/hpipe/config"
+	"github.com/crackcell/hpipe/dag"
+	"github.com/crackcell/hpipe/exec"
+	"github.com/crackcell/hpipe/log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+//===================================================================
+// Private
+//===================================================================
+
+// consulBinding ties a Consul service name to the dag.JobType it serves
+// and the exec.Exec constructor that should be bound to each healthy
+// instance's address. hpipe-agent is intentionally absent here: agent
+// pools already self-register over websocket, see exec.RemoteExec.
+type consulBinding struct {
+	jobType dag.JobType
+	newExec func(addr string) exec.Exec
+}
+
+var consulServices = map[string]consulBinding{
+	"hpipe-hadoop": {dag.HadoopJob, exec.NewHadoopExecAt},
+	"hpipe-hive":   {dag.HiveJob, exec.NewHiveExecAt},
+	"hpipe-odps":   {dag.OdpsJob, exec.NewOdpsExecAt},
+}
+
+// execPool holds the currently healthy exec.Exec backends per
+// dag.JobType, as reported by Consul. It hot-swaps as watchConsul
+// observes services coming and going.
+type execPool struct {
+	mu     sync.RWMutex
+	byType map[dag.JobType][]exec.Exec
+	next   map[dag.JobType]int
+}
+
+func newExecPool() *execPool {
+	return &execPool{
+		byType: make(map[dag.JobType][]exec.Exec),
+		next:   make(map[dag.JobType]int),
+	}
+}
+
+// get returns the next backend for t in round-robin order.
+func (this *execPool) get(t dag.JobType) (exec.Exec, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	backends := this.byType[t]
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no healthy backend for job type: %v", t)
+	}
+	idx := this.next[t] % len(backends)
+	this.next[t] = idx + 1
+	return backends[idx], nil
+}
+
+func (this *execPool) has(t dag.JobType) bool {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	return len(this.byType[t]) > 0
+}
+
+func (this *execPool) set(t dag.JobType, backends []exec.Exec) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.byType[t] = backends
+}
+
+// watchConsul blocks on Consul's /v1/health/service/<svc> long-poll API
+// and hot-swaps pool's entries for binding.jobType every time the set of
+// healthy instances changes. It never returns; run it in its own
+// goroutine per service. ready is closed once the first discovery pass
+// (success or failure) has completed, so a caller can wait out the
+// startup race instead of racing pool.has against the first blocking
+// Consul call.
+func watchConsul(pool *execPool, service string, binding consulBinding, ready chan<- struct{}) {
+	var index uint64
+	first := true
+	for {
+		addrs, newIndex, err := consulHealthyServices(service, index)
+		if err != nil {
+			log.Errorf("consul watch %s failed: %v", service, err)
+			if first {
+				first = false
+				close(ready)
+			}
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		if newIndex != index {
+			index = newIndex
+
+			backends := make([]exec.Exec, 0, len(addrs))
+			for _, addr := range addrs {
+				e := binding.newExec(addr)
+				if err := e.Setup(); err != nil {
+					log.Errorf("setup backend %s for %s failed: %v", addr, service, err)
+					continue
+				}
+				backends = append(backends, e)
+			}
+			pool.set(binding.jobType, backends)
+			log.Infof("consul: %s now has %d healthy backend(s)", service, len(backends))
+		}
+		if first {
+			first = false
+			close(ready)
+		}
+	}
+}
+
+type consulServiceEntry struct {
+	Service struct {
+		Address string `json:"Address"`
+		Port    int    `json:"Port"`
+	} `json:"Service"`
+}
+
+// consulHealthyServices issues one blocking health query for service and
+// returns the addresses of the currently passing instances, along with
+// Consul's X-Consul-Index to pass into the next blocking call.
+func consulHealthyServices(service string, index uint64) ([]string, uint64, error) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   config.ConsulAddr,
+		Path:   "/v1/health/service/" + service,
+	}
+	q := u.Query()
+	q.Set("passing", "true")
+	q.Set("index", strconv.FormatUint(index, 10))
+	q.Set("wait", "5m")
+	u.RawQuery = q.Encode()
+
+	resp, err := http.Get(u.String())
+	if err != nil {
+		return nil, index, err
+	}
+	defer resp.Body.Close()
+
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, index, err
+	}
+
+	newIndex, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		newIndex = index
+	}
+
+	addrs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		addrs = append(addrs, fmt.Sprintf("%s:%d", e.Service.Address, e.Service.Port))
+	}
+	return addrs, newIndex, nil
+}
@@ -0,0 +1,176 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * fsnotify-driven hot reload of the running flow definition
+ *
+ * @file reload.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 23:15:00 2026
+ *
+ **/
+
+package sched
+
+import (
+	"github.com/crackcell/hpipe/config"
+	"github.com/crackcell/hpipe/dag"
+	"github.com/crackcell/hpipe/flow"
+	"github.com/crackcell/hpipe/log"
+	"gopkg.in/fsnotify.v1"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+const reloadDebounce = 500 * time.Millisecond
+
+// watchFlow watches config.WorkPath recursively for changes to
+// .xml/.hcl/.yaml/.yml flow files and, after a debounce window, sends a
+// freshly re-parsed *dag.DAG on the returned channel. The channel is
+// closed if the underlying watcher dies; Sched.Run treats that as
+// "reload unavailable, keep running the original DAG." The returned
+// stop func tears the watcher down; callers must call it once they're
+// done consuming reloads, or the fsnotify goroutine leaks for good.
+func watchFlow() (reloads <-chan *dag.DAG, stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := addRecursive(watcher, config.WorkPath); err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+
+	out := make(chan *dag.DAG)
+	go func() {
+		defer close(out)
+
+		var debounce *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isFlowFile(event.Name) {
+					continue
+				}
+				if debounce == nil {
+					debounce = time.AfterFunc(reloadDebounce, func() {
+						reloadOnce(out)
+					})
+				} else {
+					debounce.Reset(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Errorf("flow watcher error: %v", err)
+			}
+		}
+	}()
+
+	return out, func() { watcher.Close() }, nil
+}
+
+// mergeDAG folds fresh into old in place, the "safe update" rules a
+// reload applies to a running DAG:
+//
+//   - surviving jobs still Started are left untouched; Run merges only
+//     between runQueue passes, so these are jobs whose tracked status
+//     was already Started when runQueue last checked them
+//   - newly-added jobs are wired in with their resolved in-degree
+//   - removed jobs are marked Failed unless already Finished, and are
+//     dropped from the DAG so they are never scheduled
+//   - every other surviving job picks up fresh's re-resolved Var (see
+//     evalMap) and dependants
+func mergeDAG(old *dag.DAG, fresh *dag.DAG) {
+	for name, job := range old.Jobs {
+		if _, ok := fresh.Jobs[name]; ok {
+			continue
+		}
+		if job.Status != dag.Finished {
+			log.Warnf("flow reload: job %s was removed from the flow, cancelling", name)
+			job.Status = dag.Failed
+		}
+		delete(old.Jobs, name)
+		delete(old.InDegrees, name)
+	}
+
+	for name, freshJob := range fresh.Jobs {
+		oldJob, ok := old.Jobs[name]
+		if !ok {
+			log.Infof("flow reload: new job %s", name)
+			old.Jobs[name] = freshJob
+			old.InDegrees[name] = fresh.InDegrees[name]
+			continue
+		}
+		if oldJob.Status == dag.Started {
+			log.Infof("flow reload: job %s is running, keeping its current run as-is", name)
+			continue
+		}
+
+		oldJob.Var = freshJob.Var
+		oldJob.Post = freshJob.Post
+		old.InDegrees[name] = fresh.InDegrees[name]
+	}
+
+	old.Relations = fresh.Relations
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+// reloadOnce re-parses config.EntryFile and, on success, hands the
+// resulting DAG to reloads. An error from dag.Build is logged and the
+// current DAG is kept. The parser returns no error: a flow file it
+// cannot read or decode ends the process (log.Fatal), and a var that
+// fails to evaluate panics.
+func reloadOnce(reloads chan<- *dag.DAG) {
+	parser := flow.NewParserForFile(config.EntryFile)
+	step := parser.ParseStepFromFile(config.EntryFile, config.WorkPath)
+
+	d, err := dag.Build(step)
+	if err != nil {
+		log.Errorf("flow reload failed, keeping current dag: %v", err)
+		return
+	}
+	reloads <- d
+}
+
+func isFlowFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".xml", ".hcl", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// addRecursive walks root and registers every directory with watcher;
+// fsnotify.Watcher.Add is not itself recursive.
+func addRecursive(watcher *fsnotify.Watcher, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
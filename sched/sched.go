@@ -20,12 +20,15 @@ package sched
 
 import (
 	"fmt"
+	"github.com/crackcell/hpipe/api"
 	"github.com/crackcell/hpipe/config"
 	"github.com/crackcell/hpipe/dag"
 	"github.com/crackcell/hpipe/exec"
 	"github.com/crackcell/hpipe/log"
 	"github.com/crackcell/hpipe/status"
 	"github.com/crackcell/hpipe/util"
+	"io"
+	"net/http"
 	"strings"
 	"sync"
 )
@@ -37,6 +40,8 @@ import (
 type Sched struct {
 	exec    map[dag.JobType]exec.Exec
 	tracker *status.StatusTracker
+	pool    *execPool
+	logger  *log.JobLogger
 }
 
 func NewSched(tracker *status.StatusTracker) (*Sched, error) {
@@ -56,16 +61,55 @@ func NewSched(tracker *status.StatusTracker) (*Sched, error) {
 		e[dag.OdpsJob] = exec.NewOdpsExec()
 	}
 
+	if config.Server {
+		pools := map[dag.JobType]*exec.RemoteExec{}
+		for _, t := range []dag.JobType{dag.HadoopJob, dag.HiveJob, dag.OdpsJob, dag.ScriptJob} {
+			if _, local := e[t]; local {
+				log.Warnf("server mode: job type %v already has a local executor configured, leaving it local instead of routing it to remote agents", t)
+				continue
+			}
+			pools[t] = exec.NewRemoteExec(t)
+		}
+		for t, pool := range pools {
+			e[t] = pool
+		}
+		go serveAgents(pools)
+	}
+
 	for _, jexec := range e {
 		if err := jexec.Setup(); err != nil {
 			return nil, err
 		}
 	}
 
-	return &Sched{
+	s := &Sched{
 		exec:    e,
 		tracker: tracker,
-	}, nil
+		logger:  log.NewJobLogger(config.MetaPath),
+	}
+
+	if config.ConsulAddr != "" {
+		s.pool = newExecPool()
+		readies := make([]chan struct{}, 0, len(consulServices))
+		for service, binding := range consulServices {
+			ready := make(chan struct{})
+			readies = append(readies, ready)
+			go watchConsul(s.pool, service, binding, ready)
+		}
+		// Block until every service has completed at least one discovery
+		// pass, otherwise a Run called right after NewSched can
+		// spuriously fail checkDAG for a Consul-only job type whose pool
+		// hasn't been populated yet.
+		for _, ready := range readies {
+			<-ready
+		}
+	}
+
+	if config.Server {
+		go serveAPI(tracker, s.logger)
+	}
+
+	return s, nil
 }
 
 func (this *Sched) Run(d *dag.DAG) error {
@@ -74,9 +118,35 @@ func (this *Sched) Run(d *dag.DAG) error {
 		return err
 	}
 
+	reloads, stop, err := watchFlow()
+	if err != nil {
+		log.Warnf("flow hot reload disabled: %v", err)
+	} else {
+		defer stop()
+	}
+
 	queue := this.genRunQueue(d)
 	for len(queue) != 0 {
 
+		// Pick up a pending reload without blocking: the DAG still has
+		// runnable/pending work, so it's worth folding an edit in before
+		// the next runQueue pass, but a reload that never arrives must
+		// not stop the run from finishing once the queue drains.
+		if reloads != nil {
+			select {
+			case fresh, ok := <-reloads:
+				if !ok {
+					reloads = nil
+				} else {
+					log.Info("flow definition changed, reloading")
+					mergeDAG(d, fresh)
+					queue = this.genRunQueue(d)
+					continue
+				}
+			default:
+			}
+		}
+
 		if err := this.runQueue(queue, d); err != nil {
 			log.Fatalf("runQueue failed: %v", err)
 			return err
@@ -101,10 +171,63 @@ func (this *Sched) Run(d *dag.DAG) error {
 	}
 }
 
+// actionRunner is the subset of exec.Exec that hpipe-agent-backed
+// executors implement to support `hpipe action`. It's a separate,
+// optional interface (checked with a type assertion in RunAction)
+// rather than a method on exec.Exec itself, since not every executor
+// needs to support actions.
+type actionRunner interface {
+	RunAction(job *dag.Job, actionName string, args []string,
+		stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+// RunAction invokes one of job's Actions (see dag.Action) outside the
+// normal DAG run, through whichever exec.Exec this job's type is bound
+// to, and records the invocation in tracker.
+func (this *Sched) RunAction(job *dag.Job, actionName string, args []string,
+	stdin io.Reader, stdout, stderr io.Writer) error {
+
+	jexec, err := this.getExec(job)
+	if err != nil {
+		return err
+	}
+
+	runner, ok := jexec.(actionRunner)
+	if !ok {
+		return fmt.Errorf("executor for job type %v does not support actions", job.Type)
+	}
+
+	err = runner.RunAction(job, actionName, args, stdin, stdout, stderr)
+	this.tracker.RecordAction(job.Name, actionName, args, err)
+	return err
+}
+
 //===================================================================
 // Private
 //===================================================================
 
+// serveAgents listens for hpipe-agent websocket connections and routes
+// each one into the RemoteExec pool(s) matching the job types it
+// advertises. It runs for the lifetime of the scheduler process.
+func serveAgents(pools map[dag.JobType]*exec.RemoteExec) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/agent", func(w http.ResponseWriter, r *http.Request) {
+		exec.ServeWS(pools, w, r)
+	})
+	if err := http.ListenAndServe(config.AgentAddr, mux); err != nil {
+		log.Fatalf("agent listener failed: %v", err)
+	}
+}
+
+// serveAPI exposes tracker's status and logger's per-job log files over
+// HTTP (GET /jobs, GET /jobs/{name}/log?attempt=N). It runs for the
+// lifetime of the scheduler process.
+func serveAPI(tracker *status.StatusTracker, logger *log.JobLogger) {
+	if err := api.Serve(config.ApiAddr, tracker, logger); err != nil {
+		log.Fatalf("job status/log API listener failed: %v", err)
+	}
+}
+
 func (this *Sched) genRunQueue(d *dag.DAG) []*dag.Job {
 	queue := []*dag.Job{}
 	for name, in := range d.InDegrees {
@@ -174,11 +297,20 @@ func (this *Sched) runQueue(queue []*dag.Job, d *dag.DAG) error {
 				this.tracker.SetStatus(job)
 				d.Builtins.SetJobReport(this.tracker.ToJson())
 
+				record, err := this.logger.Open(config.Bizdate, job.Name)
+				if err != nil {
+					log.Errorf("cannot open log for job %s: %v", job.Name, err)
+				} else {
+					log.Infof("job %s log: %s (attempt %d)", job.Name, record.Path, record.Attempt)
+				}
+
 				if err = jexec.Run(job); err != nil {
 					log.Error(err)
 					job.Status = dag.Failed
 				}
 
+				this.logger.Close(job.Name)
+
 				this.tracker.SetStatus(job)
 				d.Builtins.SetJobReport(this.tracker.ToJson())
 
@@ -191,6 +323,9 @@ func (this *Sched) runQueue(queue []*dag.Job, d *dag.DAG) error {
 }
 
 func (this *Sched) getExec(job *dag.Job) (exec.Exec, error) {
+	if this.pool != nil && this.pool.has(job.Type) {
+		return this.pool.get(job.Type)
+	}
 	if e, ok := this.exec[job.Type]; !ok {
 		return nil, fmt.Errorf("no vailid executor for job type: %v", job.Type)
 	} else {
@@ -235,9 +370,13 @@ func (this *Sched) updateDependences(job *dag.Job, d *dag.DAG) {
 
 func (this *Sched) checkDAG(d *dag.DAG) error {
 	for _, job := range d.Jobs {
-		if _, ok := this.exec[job.Type]; !ok {
-			return fmt.Errorf("no vailid executor for job type: %v", job.Type)
+		if _, ok := this.exec[job.Type]; ok {
+			continue
+		}
+		if this.pool != nil && this.pool.has(job.Type) {
+			continue
 		}
+		return fmt.Errorf("no vailid executor for job type: %v", job.Type)
 	}
 	return nil
 }
@@ -0,0 +1,58 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * bookkeeping for on-demand `hpipe action` invocations
+ *
+ * @file action.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 23:00:00 2026
+ *
+ **/
+
+package status
+
+import (
+	"time"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// ActionInvocation records one on-demand Action invocation (see
+// dag.Action) against a job in the StatusTracker, next to the job's
+// regular status. RecordAction only appends it to the tracker's
+// in-memory Actions; it does not write it to disk.
+type ActionInvocation struct {
+	Job    string
+	Action string
+	Args   []string
+	Time   time.Time
+	Err    string
+}
+
+// RecordAction appends an ActionInvocation to this tracker.
+func (this *StatusTracker) RecordAction(job, action string, args []string, err error) {
+	errStr := ""
+	if err != nil {
+		errStr = err.Error()
+	}
+	this.Actions = append(this.Actions, ActionInvocation{
+		Job:    job,
+		Action: action,
+		Args:   args,
+		Time:   time.Now(),
+		Err:    errStr,
+	})
+}
+
+//===================================================================
+// Private
+//===================================================================
@@ -0,0 +1,383 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * remote execution of jobs on registered hpipe-agent workers
+ *
+ * @file remote.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:45:00 2026
+ *
+ **/
+
+package exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crackcell/hpipe/dag"
+	"github.com/crackcell/hpipe/log"
+	"github.com/gorilla/websocket"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// callTimeout bounds how long call() waits for an agent's response. A
+// wedged agent or a connection that dies without a clean TCP close
+// would otherwise hang the call (and the sync.WaitGroup in
+// sched.runQueue that's waiting on it) forever.
+const callTimeout = 10 * time.Minute
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// RemoteExec dispatches a job to one of the worker agents registered
+// for dag.JobType, over a persistent websocket/JSON-RPC 2.0 connection,
+// instead of running CmdExec in-process on the scheduler host.
+type RemoteExec struct {
+	jobType dag.JobType
+
+	mu     sync.Mutex
+	agents []*agentConn
+	next   int
+}
+
+func NewRemoteExec(jobType dag.JobType) *RemoteExec {
+	return &RemoteExec{jobType: jobType}
+}
+
+func (this *RemoteExec) Setup() error {
+	return nil
+}
+
+func (this *RemoteExec) Run(job *dag.Job) error {
+	agent, err := this.pickAgent()
+	if err != nil {
+		return err
+	}
+	return agent.runJob(job)
+}
+
+// RunAction dispatches one of job's Actions to an agent for this job
+// type. stdin is unused: the websocket/JSON-RPC transport only streams
+// stdout/stderr back, it's accepted for interface symmetry.
+func (this *RemoteExec) RunAction(job *dag.Job, actionName string, args []string,
+	stdin io.Reader, stdout, stderr io.Writer) error {
+
+	action, ok := job.FindAction(actionName)
+	if !ok {
+		return fmt.Errorf("job %s has no action: %s", job.Name, actionName)
+	}
+
+	agent, err := this.pickAgent()
+	if err != nil {
+		return err
+	}
+	return agent.runAction(job.Name, action.Name, action.Cmd, args, stdout, stderr)
+}
+
+// Register adds a newly connected agent to this pool.
+func (this *RemoteExec) Register(agent *agentConn) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.agents = append(this.agents, agent)
+}
+
+// Unregister drops an agent, e.g. after its websocket disconnects.
+func (this *RemoteExec) Unregister(agent *agentConn) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for i, a := range this.agents {
+		if a == agent {
+			this.agents = append(this.agents[:i], this.agents[i+1:]...)
+			break
+		}
+	}
+}
+
+// ServeWS upgrades r to a websocket, registers the agent with the
+// pool(s) matching its advertised job types and pumps JSON-RPC frames
+// until it disconnects.
+func ServeWS(pools map[dag.JobType]*RemoteExec, w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Errorf("websocket upgrade failed: %v", err)
+		return
+	}
+
+	agent, err := registerAgent(conn)
+	if err != nil {
+		log.Errorf("agent registration failed: %v", err)
+		conn.Close()
+		return
+	}
+
+	for _, t := range agent.jobTypes {
+		if pool, ok := pools[t]; ok {
+			pool.Register(agent)
+			defer pool.Unregister(agent)
+		}
+	}
+
+	agent.serve()
+}
+
+//===================================================================
+// Private
+//===================================================================
+
+var wsUpgrader = websocket.Upgrader{}
+
+// pickAgent returns the next non-saturated agent in round-robin order.
+func (this *RemoteExec) pickAgent() (*agentConn, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if len(this.agents) == 0 {
+		return nil, fmt.Errorf("no agent registered for job type: %v", this.jobType)
+	}
+
+	for i := 0; i < len(this.agents); i++ {
+		idx := (this.next + i) % len(this.agents)
+		agent := this.agents[idx]
+		if agent.inflight() < agent.maxConcurrency {
+			this.next = (idx + 1) % len(this.agents)
+			return agent, nil
+		}
+	}
+	return nil, fmt.Errorf("all agents for job type %v are at max concurrency", this.jobType)
+}
+
+// agentConn is the scheduler's handle on one connected hpipe-agent.
+type agentConn struct {
+	conn           *websocket.Conn
+	jobTypes       []dag.JobType
+	maxConcurrency int
+
+	writeMu sync.Mutex // serializes conn.WriteJSON; gorilla/websocket allows only one writer at a time
+
+	mu        sync.Mutex
+	pending   map[uint64]chan RPCResponse
+	nextID    uint64
+	actionOut map[string]map[string]io.Writer // jobname -> "stdout"/"stderr", for jobs with an in-flight RunAction
+
+	running int32
+}
+
+func registerAgent(conn *websocket.Conn) (*agentConn, error) {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	req := RPCRequest{}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	if req.Method != "agent.register" {
+		return nil, fmt.Errorf("expected agent.register, got %s", req.Method)
+	}
+
+	params := AgentRegisterParams{}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	jobTypes := make([]dag.JobType, 0, len(params.JobTypes))
+	for _, t := range params.JobTypes {
+		jobTypes = append(jobTypes, dag.JobType(t))
+	}
+
+	return &agentConn{
+		conn:           conn,
+		jobTypes:       jobTypes,
+		maxConcurrency: params.MaxConcurrency,
+		pending:        make(map[uint64]chan RPCResponse),
+		actionOut:      make(map[string]map[string]io.Writer),
+	}, nil
+}
+
+func (this *agentConn) inflight() int {
+	return int(atomic.LoadInt32(&this.running))
+}
+
+func (this *agentConn) runJob(job *dag.Job) error {
+	atomic.AddInt32(&this.running, 1)
+	defer atomic.AddInt32(&this.running, -1)
+
+	params, err := json.Marshal(JobRunParams{
+		Name: job.Name,
+		Type: string(job.Type),
+		Var:  job.Var,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := this.call("job.run", params)
+	if err != nil {
+		return err
+	}
+
+	status := JobStatusParams{}
+	if err := json.Unmarshal(resp.Result, &status); err != nil {
+		return err
+	}
+	if status.Status != "Finished" {
+		return fmt.Errorf("job %s failed on agent: %s", job.Name, status.Status)
+	}
+	return nil
+}
+
+// runAction is like runJob but routes the job.stream_log notifications
+// it triggers straight to stdout/stderr instead of the log package.
+// actionOut is keyed by jobname so two concurrent RunAction calls
+// routed to this agent for different jobs don't clobber each other's
+// writers; a second concurrent action against the same job is
+// rejected outright rather than silently mixing output.
+func (this *agentConn) runAction(jobname, actionName, cmd string, args []string,
+	stdout, stderr io.Writer) error {
+
+	this.mu.Lock()
+	if _, inFlight := this.actionOut[jobname]; inFlight {
+		this.mu.Unlock()
+		return fmt.Errorf("job %s already has an action running on this agent", jobname)
+	}
+	this.actionOut[jobname] = map[string]io.Writer{"stdout": stdout, "stderr": stderr}
+	this.mu.Unlock()
+	defer func() {
+		this.mu.Lock()
+		delete(this.actionOut, jobname)
+		this.mu.Unlock()
+	}()
+
+	params, err := json.Marshal(JobActionParams{
+		Name:   jobname,
+		Action: actionName,
+		Cmd:    cmd,
+		Args:   args,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = this.call("job.action", params)
+	return err
+}
+
+// call sends a JSON-RPC request and waits for its matching response.
+func (this *agentConn) call(method string, params json.RawMessage) (*RPCResponse, error) {
+	this.mu.Lock()
+	this.nextID++
+	id := this.nextID
+	ch := make(chan RPCResponse, 1)
+	this.pending[id] = ch
+	this.mu.Unlock()
+
+	req := RPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}
+	this.writeMu.Lock()
+	err := this.conn.WriteJSON(req)
+	this.writeMu.Unlock()
+	if err != nil {
+		this.mu.Lock()
+		delete(this.pending, id)
+		this.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, fmt.Errorf("agent disconnected before responding to %s", method)
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s: %s", method, resp.Error.Message)
+		}
+		return &resp, nil
+	case <-time.After(callTimeout):
+		this.mu.Lock()
+		delete(this.pending, id)
+		this.mu.Unlock()
+		return nil, fmt.Errorf("agent timed out after %s responding to %s", callTimeout, method)
+	}
+}
+
+// serve pumps the websocket's read loop until the agent disconnects,
+// routing responses by ID and job.stream_log notifications to
+// handleNotification.
+func (this *agentConn) serve() {
+	defer this.closePending()
+	defer this.conn.Close()
+
+	for {
+		_, data, err := this.conn.ReadMessage()
+		if err != nil {
+			log.Warnf("agent connection closed: %v", err)
+			return
+		}
+
+		msg := RPCResponse{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Errorf("invalid rpc frame: %v", err)
+			continue
+		}
+
+		if msg.ID == 0 {
+			this.handleNotification(msg.Method, data)
+			continue
+		}
+
+		this.mu.Lock()
+		ch, ok := this.pending[msg.ID]
+		delete(this.pending, msg.ID)
+		this.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+}
+
+func (this *agentConn) closePending() {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for id, ch := range this.pending {
+		close(ch)
+		delete(this.pending, id)
+	}
+}
+
+func (this *agentConn) handleNotification(method string, data []byte) {
+	if method != "job.stream_log" {
+		return
+	}
+
+	req := RPCRequest{}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return
+	}
+
+	line := JobStreamLogParams{}
+	if err := json.Unmarshal(req.Params, &line); err != nil {
+		return
+	}
+
+	this.mu.Lock()
+	out := this.actionOut[line.Name]
+	this.mu.Unlock()
+
+	if w, ok := out[line.Stream]; ok && w != nil {
+		fmt.Fprintln(w, line.Line)
+		return
+	}
+	log.Info(fmt.Sprintf("<%s> %s", line.Name, line.Line))
+}
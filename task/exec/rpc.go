@@ -0,0 +1,94 @@
+/***************************************************************
+ *
+ * Copyright (c) 2026, Menglong TAN <tanmenglong@gmail.com>
+ *
+ * This program is free software; you can redistribute it
+ * and/or modify it under the terms of the GPL licence
+ *
+ **************************************************************/
+
+/**
+ * JSON-RPC 2.0 frames shared between the scheduler and hpipe-agent
+ *
+ * @file rpc.go
+ * @author Menglong TAN <tanmenglong@gmail.com>
+ * @date Wed Jul 29 21:45:00 2026
+ *
+ **/
+
+package exec
+
+import (
+	"encoding/json"
+)
+
+//===================================================================
+// Public APIs
+//===================================================================
+
+// RPCRequest is used both for calls (agent.register, job.run, job.action)
+// and for notifications (job.stream_log), which simply omit ID. It is
+// exported so the hpipe-agent binary, which lives in its own package,
+// speaks the exact same frame shape as the scheduler.
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// AgentRegisterParams is sent once by hpipe-agent right after the
+// websocket connects.
+type AgentRegisterParams struct {
+	JobTypes       []string `json:"job_types"`
+	MaxConcurrency int      `json:"max_concurrency"`
+}
+
+type JobRunParams struct {
+	Name string            `json:"name"`
+	Type string            `json:"type"`
+	Var  map[string]string `json:"var"`
+}
+
+type JobStatusParams struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type JobCancelParams struct {
+	Name string `json:"name"`
+}
+
+// JobActionParams dispatches a Job-defined Action (see dag.Action) to
+// whichever agent is running job.Type. Cmd is carried alongside Action
+// so the agent doesn't need its own copy of the job definition to run
+// it.
+type JobActionParams struct {
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Cmd    string   `json:"cmd"`
+	Args   []string `json:"args"`
+}
+
+type JobStreamLogParams struct {
+	Name   string `json:"name"`
+	Stream string `json:"stream"`
+	Line   string `json:"line"`
+}
+
+//===================================================================
+// Private
+//===================================================================
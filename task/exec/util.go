@@ -35,6 +35,23 @@ import (
 //===================================================================
 
 func CmdExec(jobname, name string, arg ...string) (int, error) {
+	return CmdExecWithSink(jobname, func(stream, line string) {
+		if stream == "stderr" {
+			log.Fatal(fmt.Sprintf("<%s> %s", jobname, line))
+		} else {
+			log.Info(fmt.Sprintf("<%s> %s", jobname, line))
+		}
+	}, name, arg...)
+}
+
+// CmdExecWithSink runs name with arg exactly like CmdExec, but hands
+// every non-empty stdout/stderr line to sink instead of writing it
+// straight to the log package. This lets callers like exec.RemoteExec's
+// hpipe-agent relay the same scanner lines as job.stream_log
+// notifications rather than local log lines.
+func CmdExecWithSink(jobname string, sink func(stream, line string),
+	name string, arg ...string) (int, error) {
+
 	cmd := exec.Command(name, arg...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -53,7 +70,7 @@ func CmdExec(jobname, name string, arg ...string) (int, error) {
 	errscanner := bufio.NewScanner(stderr)
 	for errscanner.Scan() {
 		if len(errscanner.Text()) != 0 {
-			log.Fatal(fmt.Sprintf("<%s> %s", jobname, errscanner.Text()))
+			sink("stderr", errscanner.Text())
 		}
 	}
 	if err := errscanner.Err(); err != nil {
@@ -62,7 +79,7 @@ func CmdExec(jobname, name string, arg ...string) (int, error) {
 	outscanner := bufio.NewScanner(stdout)
 	for outscanner.Scan() {
 		if len(outscanner.Text()) != 0 {
-			log.Info(fmt.Sprintf("<%s> %s", jobname, outscanner.Text()))
+			sink("stdout", outscanner.Text())
 		}
 	}
 	if err := outscanner.Err(); err != nil {